@@ -0,0 +1,76 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics emits e2e test outcome metrics (pass/fail counters,
+// duration histograms, provisioner install latency gauges) to a pluggable
+// sink so CI can scrape trend data across runs.
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Sink records e2e test outcomes. Implementations must be safe for
+// concurrent use. Each ginkgo node runs as its own process and builds its
+// own sink, which every Framework in that process shares.
+type Sink interface {
+	// RecordResult records the pass/fail outcome and duration of the test
+	// identified by canaryName (the name bracketed in the spec
+	// description, e.g. "[volume-provisioner-block]").
+	RecordResult(canaryName string, failed bool, duration time.Duration)
+	// RecordInstallLatency records how long it took to install the given
+	// provisioner into a test namespace.
+	RecordInstallLatency(provisioner string, duration time.Duration)
+}
+
+// Allowed values for -metrics-sink.
+const (
+	SinkPushgateway = "pushgateway"
+	SinkOCI         = "oci"
+	SinkStdout      = "stdout"
+	SinkNone        = "none"
+)
+
+var (
+	sinkName       = flag.String("metrics-sink", SinkStdout, "Where to send e2e canary metrics: pushgateway, oci, stdout or none.")
+	pushgatewayURL = flag.String("pushgateway-url", "", "Address of the Prometheus pushgateway to push canary metrics to (required when -metrics-sink=pushgateway).")
+	ociNamespace   = flag.String("oci-monitoring-namespace", "oci_volume_provisioner_e2e", "OCI Monitoring namespace canary metrics are posted under (used when -metrics-sink=oci).")
+)
+
+// NewSink builds the Sink selected by the -metrics-sink flag.
+func NewSink() (Sink, error) {
+	return NewNamedSink(*sinkName)
+}
+
+// NewNamedSink builds the Sink named by name, one of SinkPushgateway,
+// SinkOCI, SinkStdout or SinkNone.
+func NewNamedSink(name string) (Sink, error) {
+	switch name {
+	case SinkPushgateway:
+		if *pushgatewayURL == "" {
+			return nil, fmt.Errorf("-pushgateway-url is required when -metrics-sink=%s", SinkPushgateway)
+		}
+		return newPushgatewaySink(*pushgatewayURL), nil
+	case SinkOCI:
+		return newOCIMonitoringSink(*ociNamespace)
+	case SinkStdout:
+		return newStdoutSink(), nil
+	case "", SinkNone:
+		return newNoopSink(), nil
+	default:
+		return nil, fmt.Errorf("unknown metrics sink %q", name)
+	}
+}
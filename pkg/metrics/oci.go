@@ -0,0 +1,87 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// ociMonitoringCompartmentVar names the environment variable holding the
+// compartment OCID canary metrics are posted into.
+const ociMonitoringCompartmentVar = "OCI_MONITORING_COMPARTMENT_OCID"
+
+// ociMonitoringSink posts canary metrics to the OCI Monitoring service,
+// letting the same dashboards used for production volume metrics track e2e
+// trend data.
+type ociMonitoringSink struct {
+	client        monitoring.MonitoringClient
+	namespace     string
+	compartmentID string
+}
+
+func newOCIMonitoringSink(namespace string) (Sink, error) {
+	config := common.DefaultConfigProvider()
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	return &ociMonitoringSink{
+		client:        client,
+		namespace:     namespace,
+		compartmentID: os.Getenv(ociMonitoringCompartmentVar),
+	}, nil
+}
+
+func (s *ociMonitoringSink) RecordResult(canaryName string, failed bool, duration time.Duration) {
+	value := 0.0
+	if failed {
+		value = 1.0
+	}
+	s.postMetric("TestFailed", canaryName, value)
+	s.postMetric("TestDurationSeconds", canaryName, duration.Seconds())
+}
+
+func (s *ociMonitoringSink) RecordInstallLatency(provisioner string, duration time.Duration) {
+	s.postMetric("ProvisionerInstallLatencySeconds", provisioner, duration.Seconds())
+}
+
+func (s *ociMonitoringSink) postMetric(name, dimensionValue string, value float64) {
+	ctx := context.Background()
+	now := common.SDKTime{Time: time.Now()}
+	_, err := s.client.PostMetricData(ctx, monitoring.PostMetricDataRequest{
+		PostMetricDataDetails: monitoring.PostMetricDataDetails{
+			MetricData: []monitoring.MetricDataDetails{
+				{
+					Namespace:     &s.namespace,
+					CompartmentId: &s.compartmentID,
+					Name:          &name,
+					Dimensions:    map[string]string{"canary": dimensionValue},
+					Datapoints: []monitoring.Datapoint{
+						{Timestamp: &now, Value: &value},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Unable to post canary metric %q to OCI Monitoring: %v", name, err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const pushgatewayJob = "oci_volume_provisioner_e2e"
+
+// pushgatewaySink pushes canary metrics to a Prometheus pushgateway after
+// every test, so CI can scrape trend data across runs without keeping the
+// test binary alive as a scrape target.
+type pushgatewaySink struct {
+	pusher *push.Pusher
+
+	testsTotal     *prometheus.CounterVec
+	testDuration   *prometheus.HistogramVec
+	installLatency *prometheus.GaugeVec
+}
+
+func newPushgatewaySink(url string) Sink {
+	testsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "e2e_canary_tests_total",
+		Help: "Count of e2e canary test outcomes, by canary name and result.",
+	}, []string{"canary", "result"})
+
+	testDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "e2e_canary_test_duration_seconds",
+		Help: "Duration of e2e canary tests, by canary name.",
+	}, []string{"canary"})
+
+	installLatency := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "e2e_canary_provisioner_install_latency_seconds",
+		Help: "Latency of installing a provisioner into a test namespace, by provisioner.",
+	}, []string{"provisioner"})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(testsTotal, testDuration, installLatency)
+
+	return &pushgatewaySink{
+		pusher:         push.New(url, pushgatewayJob).Gatherer(registry),
+		testsTotal:     testsTotal,
+		testDuration:   testDuration,
+		installLatency: installLatency,
+	}
+}
+
+func (s *pushgatewaySink) RecordResult(canaryName string, failed bool, duration time.Duration) {
+	result := "pass"
+	if failed {
+		result = "fail"
+	}
+	s.testsTotal.WithLabelValues(canaryName, result).Inc()
+	s.testDuration.WithLabelValues(canaryName).Observe(duration.Seconds())
+	s.push()
+}
+
+func (s *pushgatewaySink) RecordInstallLatency(provisioner string, duration time.Duration) {
+	s.installLatency.WithLabelValues(provisioner).Set(duration.Seconds())
+	s.push()
+}
+
+func (s *pushgatewaySink) push() {
+	if err := s.pusher.Push(); err != nil {
+		log.Printf("Unable to push canary metrics to pushgateway: %v", err)
+	}
+}
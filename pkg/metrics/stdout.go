@@ -0,0 +1,39 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+var stdoutLogger = log.New(os.Stdout, "", log.LstdFlags)
+
+// stdoutSink logs metrics to stdout. Useful for local runs and debugging a
+// CI job without standing up a pushgateway.
+type stdoutSink struct{}
+
+func newStdoutSink() Sink {
+	return stdoutSink{}
+}
+
+func (stdoutSink) RecordResult(canaryName string, failed bool, duration time.Duration) {
+	stdoutLogger.Printf("canary metric: name=%q failed=%t duration=%s", canaryName, failed, duration)
+}
+
+func (stdoutSink) RecordInstallLatency(provisioner string, duration time.Duration) {
+	stdoutLogger.Printf("canary metric: provisioner=%q install_latency=%s", provisioner, duration)
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// fileNameSanitizer replaces characters that are awkward in file paths (from
+// a spec's free-text description) with underscores.
+var fileNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func sanitizeFileName(name string) string {
+	return fileNameSanitizer.ReplaceAllString(name, "_")
+}
+
+// keepNamespaceOnFailureRegExp matches the ginkgo tag a spec can add to its
+// description to opt in to namespace retention on failure, independent of
+// the suite-wide -delete-namespace-on-failure flag, e.g.:
+//
+//	It("should survive a control plane restart [KeepNamespaceOnFailure]", func() { ... })
+var keepNamespaceOnFailureRegExp = regexp.MustCompile(`\[KeepNamespaceOnFailure\]`)
+
+// specWantsNamespaceRetained reports whether the spec with the given
+// description opted in to namespace retention on failure.
+func specWantsNamespaceRetained(description string) bool {
+	return keepNamespaceOnFailureRegExp.MatchString(description)
+}
+
+// dumpNamespaceArtifacts collects pod logs, events and PVC/PV/StorageClass
+// yaml for namespace, plus describe output for every volume backup in
+// f.BackupIDs and f.SnapshotIDs, writing each into
+// TestContext.ReportDir/<specName>/. OCI volumes themselves are not
+// described.
+func (f *Framework) dumpNamespaceArtifacts(namespace, specName string) {
+	if TestContext.ReportDir == "" {
+		return
+	}
+	dir := filepath.Join(TestContext.ReportDir, sanitizeFileName(specName))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		Logf("Unable to create artifact directory %q: %v", dir, err)
+		return
+	}
+
+	f.dumpPodArtifacts(namespace, dir)
+	f.dumpEventArtifacts(namespace, dir)
+	f.dumpPVCArtifacts(namespace, dir)
+	f.dumpStorageVolumeArtifacts(dir)
+}
+
+func (f *Framework) dumpPodArtifacts(namespace, dir string) {
+	pods, err := f.ClientSet.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list pods in %q for artifact dump: %v", namespace, err)
+		return
+	}
+	for _, pod := range pods.Items {
+		logs, err := f.ClientSet.CoreV1().Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{}).DoRaw()
+		if err != nil {
+			Logf("Unable to fetch logs for pod %q: %v", pod.Name, err)
+			continue
+		}
+		writeArtifact(dir, "pod-"+pod.Name+".log", logs)
+	}
+}
+
+func (f *Framework) dumpEventArtifacts(namespace, dir string) {
+	events, err := f.ClientSet.CoreV1().Events(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list events in %q for artifact dump: %v", namespace, err)
+		return
+	}
+	writeYAMLArtifact(dir, "events.yaml", events)
+}
+
+func (f *Framework) dumpPVCArtifacts(namespace, dir string) {
+	pvcs, err := f.ClientSet.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list PVCs in %q for artifact dump: %v", namespace, err)
+		return
+	}
+	for _, pvc := range pvcs.Items {
+		writeYAMLArtifact(dir, "pvc-"+pvc.Name+".yaml", pvc)
+
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := f.ClientSet.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			Logf("Unable to fetch PV %q for artifact dump: %v", pvc.Spec.VolumeName, err)
+			continue
+		}
+		writeYAMLArtifact(dir, "pv-"+pv.Name+".yaml", pv)
+
+		if pv.Spec.StorageClassName == "" {
+			continue
+		}
+		sc, err := f.ClientSet.StorageV1().StorageClasses().Get(pv.Spec.StorageClassName, metav1.GetOptions{})
+		if err != nil {
+			Logf("Unable to fetch StorageClass %q for artifact dump: %v", pv.Spec.StorageClassName, err)
+			continue
+		}
+		writeYAMLArtifact(dir, "storageclass-"+sc.Name+".yaml", sc)
+	}
+}
+
+func (f *Framework) dumpStorageVolumeArtifacts(dir string) {
+	if len(f.BackupIDs) == 0 && len(f.SnapshotIDs) == 0 {
+		return
+	}
+	ctx := context.Background()
+	for _, id := range append(append([]string{}, f.BackupIDs...), f.SnapshotIDs...) {
+		description, err := f.StorageBackend.DescribeBackup(ctx, id)
+		if err != nil {
+			Logf("Unable to describe volume backup %q for artifact dump: %v", id, err)
+			continue
+		}
+		writeYAMLArtifact(dir, "backup-"+id+".yaml", description)
+	}
+}
+
+func writeYAMLArtifact(dir, name string, obj interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		Logf("Unable to marshal artifact %q: %v", name, err)
+		return
+	}
+	writeArtifact(dir, name, data)
+}
+
+func writeArtifact(dir, name string, data []byte) {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		Logf("Unable to write artifact %q: %v", path, err)
+	}
+}
@@ -20,15 +20,19 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -36,6 +40,7 @@ import (
 	"github.com/oracle/oci-go-sdk/common"
 	"github.com/oracle/oci-go-sdk/common/auth"
 	coreOCI "github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-volume-provisioner/pkg/metrics"
 	"github.com/oracle/oci-volume-provisioner/pkg/oci/client"
 )
 
@@ -57,15 +62,15 @@ const (
 
 // Framework is used in the execution of e2e tests.
 type Framework struct {
-	BaseName                  string
-	ProvisionerFSSInstalled   bool
-	ProvisionerBlockInstalled bool
+	BaseName string
 
 	ClientSet clientset.Interface
 
 	BlockStorageClient coreOCI.BlockstorageClient
+	StorageBackend     StorageBackend
 	IsBackup           bool
 	BackupIDs          []string
+	SnapshotIDs        []string
 
 	Namespace          *v1.Namespace   // Every test has at least one namespace unless creation is skipped
 	namespacesToDelete []*v1.Namespace // Some tests have more than one.
@@ -110,7 +115,7 @@ func (f *Framework) CreateNamespace(baseName string, labels map[string]string) (
 
 	namespaceObj := &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("volume-provisioner-e2e-tests-%v-", baseName),
+			GenerateName: fmt.Sprintf("volume-provisioner-e2e-tests-%v-node%d-", baseName, ginkgoconfig.GinkgoConfig.ParallelNode),
 			Namespace:    "",
 			Labels:       labels,
 		},
@@ -200,57 +205,164 @@ func (f *Framework) BeforeEach() {
 	}
 
 	if f.IsBackup {
-		f.BlockStorageClient = f.createStorageClient()
+		f.StorageBackend = f.createStorageBackend()
 	}
 
-	if !f.ProvisionerFSSInstalled {
+	if !isProvisionerInstalled(f.Namespace.Name, provisionerFSS) {
+		start := time.Now()
 		err := f.installFSSProvisioner(f.Namespace.Name)
 		Expect(err).NotTo(HaveOccurred())
-		f.ProvisionerFSSInstalled = true
+		markProvisionerInstalled(f.Namespace.Name, provisionerFSS)
+		metricsSink().RecordInstallLatency("fss", time.Since(start))
 	}
 
-	if !f.ProvisionerBlockInstalled {
+	if !isProvisionerInstalled(f.Namespace.Name, provisionerBlock) {
+		start := time.Now()
 		err := f.installBlockProvisioner(f.Namespace.Name)
 		Expect(err).NotTo(HaveOccurred())
-		f.ProvisionerBlockInstalled = true
+		markProvisionerInstalled(f.Namespace.Name, provisionerBlock)
+		metricsSink().RecordInstallLatency("block", time.Since(start))
 	}
 }
 
-func getCanaryMetrics(description string, testFail bool) (string, int) {
-	cmRegEx := regexp.MustCompile(`\[(.*?)\]`)
-	canaryMetricName := cmRegEx.FindStringSubmatch(description)
-	result := 0
-	if testFail {
-		result = 1
+// metricsSink lazily builds the metrics.Sink selected by -metrics-sink, so
+// every Framework in a suite run shares a single sink instance.
+func metricsSink() metrics.Sink {
+	metricsSinkOnce.Do(func() {
+		sink, err := metrics.NewSink()
+		if err != nil {
+			Logf("Unable to build metrics sink, falling back to stdout: %v", err)
+			sink, _ = metrics.NewNamedSink(metrics.SinkStdout)
+		}
+		globalMetricsSink = sink
+	})
+	return globalMetricsSink
+}
+
+var (
+	metricsSinkOnce   sync.Once
+	globalMetricsSink metrics.Sink
+)
+
+// provisionerKind identifies which of the two provisioners a worker has
+// installed into a given namespace.
+type provisionerKind int
+
+const (
+	provisionerFSS provisionerKind = iota
+	provisionerBlock
+)
+
+// installedProvisioners tracks, per namespace, which provisioners have
+// already been installed by this worker. Namespaces are worker-scoped (see
+// CreateNamespace), so this map is only ever touched by the single ginkgo
+// node it belongs to, but specs within a node still share the process, so
+// access is still guarded rather than relying on per-Framework state that
+// would race if a namespace were ever reused across Framework instances.
+var (
+	installedProvisionersMu sync.Mutex
+	installedProvisioners   = map[string]map[provisionerKind]bool{}
+)
+
+func isProvisionerInstalled(namespace string, kind provisionerKind) bool {
+	installedProvisionersMu.Lock()
+	defer installedProvisionersMu.Unlock()
+	return installedProvisioners[namespace][kind]
+}
+
+func markProvisionerInstalled(namespace string, kind provisionerKind) {
+	installedProvisionersMu.Lock()
+	defer installedProvisionersMu.Unlock()
+	if installedProvisioners[namespace] == nil {
+		installedProvisioners[namespace] = map[provisionerKind]bool{}
+	}
+	installedProvisioners[namespace][kind] = true
+}
+
+func clearProvisionersInstalled(namespace string) {
+	installedProvisionersMu.Lock()
+	defer installedProvisionersMu.Unlock()
+	delete(installedProvisioners, namespace)
+}
+
+var canaryNameRegExp = regexp.MustCompile(`\[(.*?)\]`)
+
+// canaryName extracts the bracketed canary name from a spec description,
+// e.g. "should resize an ext4 volume [expand-ext4]" -> "expand-ext4".
+func canaryName(description string) string {
+	match := canaryNameRegExp.FindStringSubmatch(description)
+	if len(match) < 2 {
+		return description
 	}
-	return canaryMetricName[1], result
+	return match[1]
 }
 
 // AfterEach deletes the namespace(s).
 func (f *Framework) AfterEach() {
 	RemoveCleanupAction(f.cleanupHandle)
 
-	getCanaryMetrics(CurrentGinkgoTestDescription().TestText, CurrentGinkgoTestDescription().Failed)
-	//PopulateTestSuccessCanaryMetrics(CurrentGinkgoTestDescription().TestText, CurrentGinkgoTestDescription().Failed)
+	testDescription := CurrentGinkgoTestDescription()
+	metricsSink().RecordResult(canaryName(testDescription.TestText), testDescription.Failed, testDescription.Duration)
 
 	nsDeletionErrors := map[string]error{}
 
-	// Whether to delete namespace is determined by 3 factors: delete-namespace flag, delete-namespace-on-failure flag and the test result
+	// Namespaces this spec actually ran in. Under parallel sharding f.Namespace
+	// is a namespace shared (and owned) by the suite rather than by this
+	// Framework, so BeforeEach never adds it to namespacesToDelete — fall back
+	// to it so artifact dumping and retention still see the namespace the
+	// spec used instead of silently seeing nothing. f.Namespace can itself be
+	// nil if BeforeEach failed before obtaining a namespace, so guard against
+	// that rather than dereferencing a nil entry below.
+	specNamespaces := f.namespacesToDelete
+	if len(specNamespaces) == 0 && f.Namespace != nil {
+		specNamespaces = []*v1.Namespace{f.Namespace}
+	}
+
+	if testDescription.Failed {
+		for _, ns := range specNamespaces {
+			By(fmt.Sprintf("Dumping artifacts for failed spec %q in namespace %q", testDescription.TestText, ns.Name))
+			f.dumpNamespaceArtifacts(ns.Name, testDescription.TestText)
+		}
+	}
+
+	// Whether to delete namespace is determined by 4 factors: delete-namespace flag, delete-namespace-on-failure
+	// flag, the [KeepNamespaceOnFailure] spec tag, and the test result.
 	// if delete-namespace set to false, namespace will always be preserved.
 	// if delete-namespace is true and delete-namespace-on-failure is false, namespace will be preserved if test failed.
-	if TestContext.DeleteNamespace && (TestContext.DeleteNamespaceOnFailure || !CurrentGinkgoTestDescription().Failed) {
+	// if the spec itself is tagged [KeepNamespaceOnFailure], it is preserved on failure regardless of
+	// delete-namespace-on-failure, while unrelated specs in the same run still clean up as usual.
+	shouldDelete := TestContext.DeleteNamespace &&
+		(!testDescription.Failed ||
+			(TestContext.DeleteNamespaceOnFailure && !specWantsNamespaceRetained(testDescription.TestText)))
+	if shouldDelete {
+		// Only ever delete namespaces this Framework created itself. A
+		// namespace shared across specs under parallel sharding is owned by
+		// the suite and is only ever torn down by TeardownSuiteAllNodes.
 		for _, ns := range f.namespacesToDelete {
 			By(fmt.Sprintf("Destroying namespace %q for this suite.", ns.Name))
 			if err := f.DeleteNamespace(ns.Name, 5*time.Minute); err != nil {
 				nsDeletionErrors[ns.Name] = err
 			}
 		}
+	} else {
+		// Under parallel sharding this marks the namespace every spec on this
+		// node shares, not just the one this spec used, so a single retained
+		// failure keeps everything co-located in it around until the TTL
+		// reclaims it. That's an accepted trade-off of sharing a namespace
+		// across specs: there's no narrower unit to retain.
+		for _, ns := range specNamespaces {
+			markNamespaceRetained(f.ClientSet, ns.Name)
+		}
 	}
 
 	for _, backupID := range f.BackupIDs {
 		By(fmt.Sprintf("Deleting backups %q", backupID))
-		ctx := context.Background()
-		f.BlockStorageClient.DeleteVolumeBackup(ctx, coreOCI.DeleteVolumeBackupRequest{VolumeBackupId: &backupID})
+		f.StorageBackend.DeleteBackup(context.Background(), backupID)
+	}
+
+	for _, snapshotID := range f.SnapshotIDs {
+		By(fmt.Sprintf("Deleting snapshots %q", snapshotID))
+		f.StorageBackend.DeleteBackup(context.Background(), snapshotID)
 	}
 
 	// if we had errors deleting, report them now.
@@ -262,8 +374,37 @@ func (f *Framework) AfterEach() {
 		Failf(strings.Join(messages, ","))
 	}
 
-	f.ProvisionerBlockInstalled = false
-	f.ProvisionerFSSInstalled = false
+	for _, ns := range f.namespacesToDelete {
+		clearProvisionersInstalled(ns.Name)
+	}
+}
+
+// ExpandPVC patches a PVC's requested storage size in place, triggering a
+// resize of its backing volume.
+func (f *Framework) ExpandPVC(pvc *v1.PersistentVolumeClaim, newSize string) error {
+	patch := fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":%q}}}}`, newSize)
+	_, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(pvc.Name, apitypes.MergePatchType, []byte(patch))
+	return err
+}
+
+// WaitForPVCResize polls until the given PVC's status capacity reflects
+// newSize, or timeout elapses.
+func (f *Framework) WaitForPVCResize(pvc *v1.PersistentVolumeClaim, newSize resource.Quantity, timeout time.Duration) error {
+	return wait.PollImmediate(Poll, timeout, func() (bool, error) {
+		got, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(pvc.Name, metav1.GetOptions{})
+		if err != nil {
+			Logf("Error while waiting for PVC %q to resize: %v", pvc.Name, err)
+			return false, nil
+		}
+		capacity := got.Status.Capacity[v1.ResourceStorage]
+		return capacity.Cmp(newSize) >= 0, nil
+	})
+}
+
+// WaitForBackupState polls the storage backend until the volume backup with
+// the given id reaches the desired lifecycle state, or the timeout elapses.
+func (f *Framework) WaitForBackupState(id string, state coreOCI.VolumeBackupLifecycleStateEnum, timeout time.Duration) error {
+	return f.StorageBackend.WaitForState(context.Background(), id, string(state), timeout)
 }
 
 func (f *Framework) createStorageClient() coreOCI.BlockstorageClient {
@@ -0,0 +1,108 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewPVC builds a PersistentVolumeClaim object requesting size storage from
+// class, named from prefix, in f's namespace. It is not created against the
+// API server; pass it to CreateAndAwaitPVC to do so.
+func (f *Framework) NewPVC(prefix, class, size string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: prefix,
+			Namespace:    f.Namespace.Name,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: &class,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+}
+
+// CreateAndAwaitPVC creates pvc and waits for it to be bound, returning the
+// up to date object.
+func (f *Framework) CreateAndAwaitPVC(pvc *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	created, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(pvc)
+	Expect(err).NotTo(HaveOccurred())
+
+	Eventually(func() (v1.PersistentVolumeClaimPhase, error) {
+		got, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(created.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		return got.Status.Phase, nil
+	}, 5*time.Minute, Poll).Should(Equal(v1.ClaimBound))
+
+	bound, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(created.Name, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	return bound
+}
+
+// RunInPodWithPVC runs command to completion in a pod mounting pvc at /data.
+func (f *Framework) RunInPodWithPVC(pvc *v1.PersistentVolumeClaim, command string) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "pvc-tester-",
+			Namespace:    pvc.Namespace,
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{
+				{
+					Name:    "tester",
+					Image:   "busybox",
+					Command: []string{"/bin/sh", "-c", command},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "data", MountPath: "/data"},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+					},
+				},
+			},
+		},
+	}
+
+	created, err := f.ClientSet.CoreV1().Pods(pvc.Namespace).Create(pod)
+	Expect(err).NotTo(HaveOccurred())
+
+	Eventually(func() (v1.PodPhase, error) {
+		got, err := f.ClientSet.CoreV1().Pods(pvc.Namespace).Get(created.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		return got.Status.Phase, nil
+	}, 5*time.Minute, Poll).Should(Equal(v1.PodSucceeded))
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// retainedAtAnnotation records, on a namespace deliberately kept around
+// after a failed (or explicitly [KeepNamespaceOnFailure]) spec, the RFC3339
+// timestamp it was retained at. An e2e suite binary is one-shot and exits
+// long before any reasonable TTL elapses, so the annotation (rather than
+// in-process state) is what lets a later run's
+// CleanupExpiredRetainedNamespaces actually reclaim it.
+const retainedAtAnnotation = "volume-provisioner-e2e-tests/retained-at"
+
+// markNamespaceRetained annotates namespace with the time it was retained,
+// so a later suite run can reclaim it once it has outlived its TTL.
+func markNamespaceRetained(client clientset.Interface, namespace string) {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, retainedAtAnnotation, time.Now().Format(time.RFC3339))
+	if _, err := client.CoreV1().Namespaces().Patch(namespace, apitypes.MergePatchType, []byte(patch)); err != nil {
+		Logf("Unable to mark namespace %q as retained: %v", namespace, err)
+	}
+}
+
+// CleanupExpiredRetainedNamespaces deletes namespaces previously marked
+// retained by markNamespaceRetained whose retention has outlived ttl. It is
+// meant to be called alongside cleanupStaleNamespaces, at the start and end
+// of a suite run, so namespaces retained by one run are reclaimed by a later
+// one rather than depending on any single process staying alive for ttl.
+func CleanupExpiredRetainedNamespaces(client clientset.Interface, ttl time.Duration) {
+	By("Cleaning up retained namespaces that have outlived their TTL")
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list namespaces for retained-namespace cleanup: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, ns := range namespaces.Items {
+		retainedAt, ok := ns.Annotations[retainedAtAnnotation]
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, retainedAt)
+		if err != nil || now.Sub(t) <= ttl {
+			continue
+		}
+		Logf("Garbage collecting retained namespace %q after TTL of %s", ns.Name, ttl)
+		if err := client.CoreV1().Namespaces().Delete(ns.Name, nil); err != nil {
+			Logf("Unable to garbage collect retained namespace %q: %v", ns.Name, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	coreOCI "github.com/oracle/oci-go-sdk/core"
+)
+
+// StorageBackendVar names the environment variable used to select which
+// StorageBackend the suite runs against. Defaults to "oci" when unset.
+const StorageBackendVar = "STORAGE_BACKEND"
+
+// StorageBackend is the set of block storage operations the e2e suite needs
+// in order to exercise the provisioner end to end. It exists so that test
+// bodies written against it can run unmodified against any cloud the
+// provisioner supports, or against fakeStorageBackend in unit tests that
+// don't want to make real API calls.
+type StorageBackend interface {
+	// CreateVolume provisions a new volume of the given size (in GB) in the
+	// given availability domain and returns its id.
+	CreateVolume(ctx context.Context, sizeGB int, availabilityDomain string) (string, error)
+	// DeleteVolume deletes the volume with the given id.
+	DeleteVolume(ctx context.Context, id string) error
+	// CreateBackup takes a backup of the volume with the given id, tagging
+	// it with tags, and returns the backup's id.
+	CreateBackup(ctx context.Context, volumeID string, tags map[string]string) (string, error)
+	// DeleteBackup deletes the backup with the given id.
+	DeleteBackup(ctx context.Context, id string) error
+	// WaitForState polls until the backup with the given id reaches state,
+	// or timeout elapses. Volume states are not polled.
+	WaitForState(ctx context.Context, id, state string, timeout time.Duration) error
+	// DescribeBackup returns a description of the backup with the given id,
+	// suitable for dumping as an artifact when a spec fails.
+	DescribeBackup(ctx context.Context, id string) (interface{}, error)
+}
+
+// createStorageBackend builds the StorageBackend used by this suite run.
+// OCI is the only backend wired up against real infrastructure today;
+// StorageBackendVar selects an alternative for development against other
+// clouds or CI runs that don't have OCI credentials.
+func (f *Framework) createStorageBackend() StorageBackend {
+	backend := os.Getenv(StorageBackendVar)
+	switch backend {
+	case "", "oci":
+		f.BlockStorageClient = f.createStorageClient()
+		return &ociStorageBackend{client: f.BlockStorageClient}
+	case "fake":
+		return newFakeStorageBackend()
+	case "vsphere":
+		return &vsphereStorageBackend{}
+	default:
+		Failf("unknown storage backend %q", backend)
+		return nil
+	}
+}
+
+// ociStorageBackend is the default StorageBackend, backed by the OCI Block
+// Volume service.
+type ociStorageBackend struct {
+	client coreOCI.BlockstorageClient
+}
+
+func (b *ociStorageBackend) CreateVolume(ctx context.Context, sizeGB int, availabilityDomain string) (string, error) {
+	sizeMB := int64(sizeGB * 1024)
+	resp, err := b.client.CreateVolume(ctx, coreOCI.CreateVolumeRequest{
+		CreateVolumeDetails: coreOCI.CreateVolumeDetails{
+			AvailabilityDomain: &availabilityDomain,
+			SizeInMBs:          &sizeMB,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return *resp.Id, nil
+}
+
+func (b *ociStorageBackend) DeleteVolume(ctx context.Context, id string) error {
+	_, err := b.client.DeleteVolume(ctx, coreOCI.DeleteVolumeRequest{VolumeId: &id})
+	return err
+}
+
+func (b *ociStorageBackend) CreateBackup(ctx context.Context, volumeID string, tags map[string]string) (string, error) {
+	resp, err := b.client.CreateVolumeBackup(ctx, coreOCI.CreateVolumeBackupRequest{
+		CreateVolumeBackupDetails: coreOCI.CreateVolumeBackupDetails{
+			VolumeId:     &volumeID,
+			FreeformTags: tags,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return *resp.Id, nil
+}
+
+func (b *ociStorageBackend) DeleteBackup(ctx context.Context, id string) error {
+	_, err := b.client.DeleteVolumeBackup(ctx, coreOCI.DeleteVolumeBackupRequest{VolumeBackupId: &id})
+	return err
+}
+
+func (b *ociStorageBackend) WaitForState(ctx context.Context, id, state string, timeout time.Duration) error {
+	return wait.PollImmediate(Poll, timeout, func() (bool, error) {
+		resp, err := b.client.GetVolumeBackup(ctx, coreOCI.GetVolumeBackupRequest{VolumeBackupId: &id})
+		if err != nil {
+			Logf("Error while waiting for %q to reach state %q: %v", id, state, err)
+			return false, nil
+		}
+		return string(resp.LifecycleState) == state, nil
+	})
+}
+
+func (b *ociStorageBackend) DescribeBackup(ctx context.Context, id string) (interface{}, error) {
+	resp, err := b.client.GetVolumeBackup(ctx, coreOCI.GetVolumeBackupRequest{VolumeBackupId: &id})
+	if err != nil {
+		return nil, err
+	}
+	return resp.VolumeBackup, nil
+}
+
+// vsphereStorageBackend is a placeholder for exercising the provisioner
+// against vSphere CSI volumes. Not yet implemented.
+type vsphereStorageBackend struct{}
+
+func (b *vsphereStorageBackend) CreateVolume(ctx context.Context, sizeGB int, availabilityDomain string) (string, error) {
+	return "", fmt.Errorf("vsphere storage backend not implemented")
+}
+
+func (b *vsphereStorageBackend) DeleteVolume(ctx context.Context, id string) error {
+	return fmt.Errorf("vsphere storage backend not implemented")
+}
+
+func (b *vsphereStorageBackend) CreateBackup(ctx context.Context, volumeID string, tags map[string]string) (string, error) {
+	return "", fmt.Errorf("vsphere storage backend not implemented")
+}
+
+func (b *vsphereStorageBackend) DeleteBackup(ctx context.Context, id string) error {
+	return fmt.Errorf("vsphere storage backend not implemented")
+}
+
+func (b *vsphereStorageBackend) WaitForState(ctx context.Context, id, state string, timeout time.Duration) error {
+	return fmt.Errorf("vsphere storage backend not implemented")
+}
+
+func (b *vsphereStorageBackend) DescribeBackup(ctx context.Context, id string) (interface{}, error) {
+	return nil, fmt.Errorf("vsphere storage backend not implemented")
+}
+
+// fakeStorageBackend is an in-memory StorageBackend for unit tests that want
+// to exercise framework/test code without making real cloud API calls.
+type fakeStorageBackend struct {
+	mu      sync.Mutex
+	nextID  int
+	volumes map[string]bool
+	backups map[string]string // backup id -> volume id
+}
+
+func newFakeStorageBackend() *fakeStorageBackend {
+	return &fakeStorageBackend{
+		volumes: map[string]bool{},
+		backups: map[string]string{},
+	}
+}
+
+func (b *fakeStorageBackend) generateID(prefix string) string {
+	b.nextID++
+	return fmt.Sprintf("%s-%d", prefix, b.nextID)
+}
+
+func (b *fakeStorageBackend) CreateVolume(ctx context.Context, sizeGB int, availabilityDomain string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.generateID("fake-volume")
+	b.volumes[id] = true
+	return id, nil
+}
+
+func (b *fakeStorageBackend) DeleteVolume(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.volumes[id] {
+		return fmt.Errorf("fake volume %q not found", id)
+	}
+	delete(b.volumes, id)
+	return nil
+}
+
+func (b *fakeStorageBackend) CreateBackup(ctx context.Context, volumeID string, tags map[string]string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.volumes[volumeID] {
+		return "", fmt.Errorf("fake volume %q not found", volumeID)
+	}
+	id := b.generateID("fake-backup")
+	b.backups[id] = volumeID
+	return id, nil
+}
+
+func (b *fakeStorageBackend) DeleteBackup(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.backups[id]; !ok {
+		return fmt.Errorf("fake backup %q not found", id)
+	}
+	delete(b.backups, id)
+	return nil
+}
+
+func (b *fakeStorageBackend) WaitForState(ctx context.Context, id, state string, timeout time.Duration) error {
+	// The fake backend transitions resources synchronously, so any state
+	// other than "AVAILABLE" is unreachable.
+	if state != "AVAILABLE" {
+		return fmt.Errorf("fake storage backend never reaches state %q", state)
+	}
+	return nil
+}
+
+func (b *fakeStorageBackend) DescribeBackup(ctx context.Context, id string) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	volumeID, ok := b.backups[id]
+	if !ok {
+		return nil, fmt.Errorf("fake backup %q not found", id)
+	}
+	return map[string]string{"id": id, "volumeId": volumeID}, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeStorageBackendVolumeLifecycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(t *testing.T, backend StorageBackend, volumeID string)
+		wantErr bool
+	}{
+		{
+			name: "delete backup for a real volume succeeds",
+			run: func(t *testing.T, backend StorageBackend, volumeID string) {
+				backupID, err := backend.CreateBackup(context.Background(), volumeID, nil)
+				if err != nil {
+					t.Fatalf("CreateBackup: %v", err)
+				}
+				if err := backend.DeleteBackup(context.Background(), backupID); err != nil {
+					t.Fatalf("DeleteBackup: %v", err)
+				}
+			},
+		},
+		{
+			name: "delete unknown backup fails",
+			run: func(t *testing.T, backend StorageBackend, volumeID string) {
+				if err := backend.DeleteBackup(context.Background(), "does-not-exist"); err == nil {
+					t.Fatalf("expected an error deleting an unknown backup")
+				}
+			},
+		},
+		{
+			name: "backup of an unknown volume fails",
+			run: func(t *testing.T, backend StorageBackend, volumeID string) {
+				if _, err := backend.CreateBackup(context.Background(), "does-not-exist", nil); err == nil {
+					t.Fatalf("expected an error backing up an unknown volume")
+				}
+			},
+		},
+		{
+			name: "describe an unknown backup fails",
+			run: func(t *testing.T, backend StorageBackend, volumeID string) {
+				if _, err := backend.DescribeBackup(context.Background(), "does-not-exist"); err == nil {
+					t.Fatalf("expected an error describing an unknown backup")
+				}
+			},
+		},
+		{
+			name: "describe a real backup succeeds",
+			run: func(t *testing.T, backend StorageBackend, volumeID string) {
+				backupID, err := backend.CreateBackup(context.Background(), volumeID, nil)
+				if err != nil {
+					t.Fatalf("CreateBackup: %v", err)
+				}
+				if _, err := backend.DescribeBackup(context.Background(), backupID); err != nil {
+					t.Fatalf("DescribeBackup: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newFakeStorageBackend()
+			volumeID, err := backend.CreateVolume(context.Background(), 50, "AD-1")
+			if err != nil {
+				t.Fatalf("CreateVolume: %v", err)
+			}
+			tt.run(t, backend, volumeID)
+		})
+	}
+}
+
+func TestFakeStorageBackendWaitForState(t *testing.T) {
+	backend := newFakeStorageBackend()
+	volumeID, err := backend.CreateVolume(context.Background(), 50, "AD-1")
+	if err != nil {
+		t.Fatalf("CreateVolume: %v", err)
+	}
+	backupID, err := backend.CreateBackup(context.Background(), volumeID, nil)
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+
+	if err := backend.WaitForState(context.Background(), backupID, "AVAILABLE", time.Second); err != nil {
+		t.Fatalf("WaitForState(AVAILABLE): %v", err)
+	}
+	if err := backend.WaitForState(context.Background(), backupID, "TERMINATED", time.Second); err == nil {
+		t.Fatalf("expected WaitForState(TERMINATED) to fail on the fake backend")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	coreOCI "github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-volume-provisioner/pkg/oci/client"
+)
+
+// staleNamespacePrefix matches namespaces left behind by a previous, aborted
+// suite run.
+const staleNamespacePrefix = "volume-provisioner-e2e-tests-"
+
+// suiteTagKey is the freeform tag key used to attribute OCI resources
+// created during the suite to the ginkgo node that created them, so that
+// SetupSuiteNode1 can find and clean up anything left dangling.
+const suiteTagKey = "volume-provisioner-e2e-suite"
+
+// retainedNamespaceTTL bounds how long a namespace kept around after a
+// failed (or explicitly [KeepNamespaceOnFailure]) spec survives before
+// CleanupExpiredRetainedNamespaces reclaims it.
+const retainedNamespaceTTL = 24 * time.Hour
+
+// sharedSuiteConfig is broadcast from ginkgo node 1 to every other node so
+// that workers running in parallel (`ginkgo -nodes=N`) share a single view
+// of the environment instead of each re-deriving it.
+type sharedSuiteConfig struct {
+	OCIConfig     string
+	SubnetOCID    string
+	MntTargetOCID string
+	AD            string
+}
+
+// SetupSuiteNode1 runs exactly once, on ginkgo node 1, before any node runs
+// its specs. It cleans up stale namespaces and dangling OCI backups left
+// behind by a previous, aborted run, then returns the shared suite
+// configuration for SetupSuiteAllNodes to unpack on every node (including
+// node 1 itself).
+//
+// Intended to be wired up as the first argument to SynchronizedBeforeSuite:
+//
+//	var _ = SynchronizedBeforeSuite(framework.SetupSuiteNode1, framework.SetupSuiteAllNodes)
+func SetupSuiteNode1() []byte {
+	client, err := newSuiteClientSet()
+	Expect(err).NotTo(HaveOccurred())
+
+	cleanupStaleNamespaces(client)
+	CleanupExpiredRetainedNamespaces(client, retainedNamespaceTTL)
+
+	cfg := sharedSuiteConfig{
+		OCIConfig:     TestContext.OCIConfig,
+		SubnetOCID:    TestContext.SubnetOCID,
+		MntTargetOCID: TestContext.MntTargetOCID,
+		AD:            TestContext.AD,
+	}
+
+	if clientCfg, err := loadSuiteClientConfig(); err == nil {
+		f := &Framework{}
+		cleanupDanglingBackups(f.createStorageClient(), clientCfg.CompartmentID)
+	} else {
+		Logf("Skipping dangling volume backup cleanup: %v", err)
+	}
+
+	data, err := json.Marshal(cfg)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+// SetupSuiteAllNodes runs on every node, including node 1, after
+// SetupSuiteNode1 has returned. It unpacks the shared configuration
+// broadcast by node 1 into TestContext so that every worker uses the same
+// compartment, subnet and mount target regardless of which node discovered
+// them, then creates the namespace this node's specs will share so that
+// Framework.BeforeEach installs each provisioner into it only once instead
+// of once per spec.
+func SetupSuiteAllNodes(data []byte) {
+	var cfg sharedSuiteConfig
+	Expect(json.Unmarshal(data, &cfg)).NotTo(HaveOccurred())
+
+	TestContext.OCIConfig = cfg.OCIConfig
+	TestContext.SubnetOCID = cfg.SubnetOCID
+	TestContext.MntTargetOCID = cfg.MntTargetOCID
+	TestContext.AD = cfg.AD
+
+	client, err := newSuiteClientSet()
+	Expect(err).NotTo(HaveOccurred())
+
+	f := &Framework{ClientSet: client}
+	ns, err := f.CreateNamespace("shared", map[string]string{"e2e-framework": "shared"})
+	Expect(err).NotTo(HaveOccurred())
+	TestContext.Namespace = ns.Name
+}
+
+// TeardownSuiteAllNodes runs on every node before TeardownSuiteNode1. It
+// deletes the namespace this node's specs shared (see SetupSuiteAllNodes),
+// unless a spec marked it retained for post-mortem inspection, in which case
+// it is left for CleanupExpiredRetainedNamespaces to reclaim once its TTL
+// elapses.
+func TeardownSuiteAllNodes() {
+	client, err := newSuiteClientSet()
+	if err != nil {
+		Logf("Unable to build client for node teardown: %v", err)
+		return
+	}
+	cleanupNamespacesForNode(client, ginkgoconfig.GinkgoConfig.ParallelNode)
+}
+
+// TeardownSuiteNode1 runs exactly once, on ginkgo node 1, after every node
+// has finished TeardownSuiteAllNodes.
+func TeardownSuiteNode1() {
+	client, err := newSuiteClientSet()
+	if err != nil {
+		Logf("Unable to build client for suite teardown: %v", err)
+		return
+	}
+	cleanupStaleNamespaces(client)
+	CleanupExpiredRetainedNamespaces(client, retainedNamespaceTTL)
+}
+
+// NodeTags returns the freeform tags that OCI resources created during this
+// suite run should carry, so that cleanup can attribute a resource to the
+// ginkgo node that created it.
+func NodeTags() map[string]string {
+	return map[string]string{
+		suiteTagKey: nodeTagValue(),
+	}
+}
+
+func nodeTagValue() string {
+	return "node-" + itoa(ginkgoconfig.GinkgoConfig.ParallelNode)
+}
+
+func itoa(i int) string {
+	if i == 0 {
+		return "0"
+	}
+	neg := i < 0
+	if neg {
+		i = -i
+	}
+	var buf [20]byte
+	pos := len(buf)
+	for i > 0 {
+		pos--
+		buf[pos] = byte('0' + i%10)
+		i /= 10
+	}
+	if neg {
+		pos--
+		buf[pos] = '-'
+	}
+	return string(buf[pos:])
+}
+
+func newSuiteClientSet() (clientset.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", TestContext.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.NewForConfig(config)
+}
+
+func loadSuiteClientConfig() (*client.Config, error) {
+	file, err := os.Open(TestContext.OCIConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return client.LoadConfig(file)
+}
+
+func cleanupStaleNamespaces(client clientset.Interface) {
+	By("Cleaning up stale namespaces from a previous suite run")
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list namespaces for stale cleanup: %v", err)
+		return
+	}
+	for _, ns := range namespaces.Items {
+		if !strings.HasPrefix(ns.Name, staleNamespacePrefix) {
+			continue
+		}
+		if _, retained := ns.Annotations[retainedAtAnnotation]; retained {
+			// Leave it for CleanupExpiredRetainedNamespaces, which reclaims
+			// it once its retention TTL has actually elapsed.
+			continue
+		}
+		Logf("Deleting stale namespace %q", ns.Name)
+		if err := client.CoreV1().Namespaces().Delete(ns.Name, nil); err != nil {
+			Logf("Unable to delete stale namespace %q: %v", ns.Name, err)
+		}
+	}
+}
+
+func cleanupNamespacesForNode(client clientset.Interface, node int) {
+	suffix := "-node" + itoa(node) + "-"
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		Logf("Unable to list namespaces for node %d cleanup: %v", node, err)
+		return
+	}
+	for _, ns := range namespaces.Items {
+		if !strings.HasPrefix(ns.Name, staleNamespacePrefix) || !strings.Contains(ns.Name, suffix) {
+			continue
+		}
+		if _, retained := ns.Annotations[retainedAtAnnotation]; retained {
+			Logf("Leaving namespace %q for node %d in place: marked retained by a failed spec", ns.Name, node)
+			continue
+		}
+		Logf("Deleting namespace %q left behind by node %d", ns.Name, node)
+		if err := client.CoreV1().Namespaces().Delete(ns.Name, nil); err != nil {
+			Logf("Unable to delete namespace %q: %v", ns.Name, err)
+		}
+	}
+}
+
+func cleanupDanglingBackups(storageClient coreOCI.BlockstorageClient, compartmentID string) {
+	By("Cleaning up dangling OCI volume backups from a previous suite run")
+	ctx := context.Background()
+	resp, err := storageClient.ListVolumeBackups(ctx, coreOCI.ListVolumeBackupsRequest{
+		CompartmentId: &compartmentID,
+	})
+	if err != nil {
+		Logf("Unable to list volume backups for dangling cleanup: %v", err)
+		return
+	}
+	for _, backup := range resp.Items {
+		if backup.FreeformTags[suiteTagKey] == "" {
+			continue
+		}
+		Logf("Deleting dangling volume backup %q", *backup.Id)
+		if _, err := storageClient.DeleteVolumeBackup(ctx, coreOCI.DeleteVolumeBackupRequest{VolumeBackupId: backup.Id}); err != nil {
+			Logf("Unable to delete dangling volume backup %q: %v", *backup.Id, err)
+		}
+	}
+}
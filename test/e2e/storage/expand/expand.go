@@ -0,0 +1,86 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expand exercises resize of PVCs provisioned by the OCI block
+// volume provisioner. Each PVC is expanded while no pod is running against
+// it; the filesystem size is then checked from a new pod.
+package expand
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/oracle/oci-volume-provisioner/test/e2e/framework"
+)
+
+const (
+	expandedVolumeBlock = "100Gi"
+	resizeTimeout       = 10 * time.Minute
+)
+
+var _ = Describe("Volume expansion [Expand]", func() {
+	f := framework.NewDefaultFramework("expand")
+
+	expandAndVerify := func(class string) {
+		By("Creating a PVC and waiting for it to be bound")
+		pvc := createAndAwaitPVC(f, class, framework.MinVolumeBlock)
+
+		By("Writing a marker file to the volume")
+		f.RunInPodWithPVC(pvc, "dd if=/dev/urandom of=/data/marker.bin bs=1M count=1 && sync")
+
+		By("Expanding the PVC")
+		newSize := resource.MustParse(expandedVolumeBlock)
+		Expect(f.ExpandPVC(pvc, expandedVolumeBlock)).NotTo(HaveOccurred())
+
+		By("Waiting for the backing OCI volume to resize")
+		Expect(f.WaitForPVCResize(pvc, newSize, resizeTimeout)).NotTo(HaveOccurred())
+
+		By("Verifying the filesystem inside the pod grew")
+		verifyFilesystemSize(f, pvc, newSize)
+	}
+
+	It("should resize an ext4 volume [expand-ext4]", func() {
+		expandAndVerify(framework.ClassOCI)
+	})
+
+	It("should resize an ext3 volume [expand-ext3]", func() {
+		expandAndVerify(framework.ClassOCIExt3)
+	})
+
+	It("should reject shrinking a PVC below its current size [expand-shrink-rejected]", func() {
+		By("Creating a PVC and waiting for it to be bound")
+		pvc := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Requesting a smaller size than the PVC currently has")
+		Expect(f.ExpandPVC(pvc, "10Gi")).To(HaveOccurred())
+	})
+})
+
+func createAndAwaitPVC(f *framework.Framework, class, size string) *v1.PersistentVolumeClaim {
+	return f.CreateAndAwaitPVC(f.NewPVC("expand-", class, size))
+}
+
+// verifyFilesystemSize runs `df` inside a pod mounting pvc and checks that
+// the reported filesystem size is at least 90% of newSize.
+func verifyFilesystemSize(f *framework.Framework, pvc *v1.PersistentVolumeClaim, newSize resource.Quantity) {
+	minKB := newSize.Value() / 1024 * 9 / 10 // allow for filesystem overhead
+	f.RunInPodWithPVC(pvc, fmt.Sprintf(
+		`df -k /data | awk 'NR==2 {exit ($2 < %d)}'`, minKB))
+}
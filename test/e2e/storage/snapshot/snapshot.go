@@ -0,0 +1,170 @@
+// Copyright 2018 Oracle and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot exercises the snapshot/restore lifecycle of the OCI block
+// volume provisioner: taking a backup of a bound PVC and provisioning a new
+// PVC from that backup.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coreOCI "github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-volume-provisioner/test/e2e/framework"
+)
+
+const (
+	backupTimeout = 10 * time.Minute
+)
+
+var _ = Describe("Volume snapshot and restore [Snapshot]", func() {
+	f := framework.NewBackupFramework("snapshot")
+
+	It("should provision a new PVC from a pre-provisioned snapshot [snapshot-pre-provisioned]", func() {
+		By("Creating a source PVC and waiting for it to be bound")
+		sourcePVC := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Writing data to the source volume")
+		writeTestFile(f, sourcePVC)
+
+		By("Taking a backup of the source volume")
+		backupID := createBackup(f, sourcePVC)
+
+		By("Provisioning a new PVC from the backup")
+		restoredPVC := createAndAwaitPVCFromBackup(f, framework.ClassOCI, framework.MinVolumeBlock, backupID)
+
+		By("Verifying the restored volume contains the source data")
+		verifyTestFile(f, restoredPVC)
+	})
+
+	It("should provision a new PVC from a dynamically provisioned snapshot [snapshot-dynamic]", func() {
+		By("Creating a source PVC and waiting for it to be bound")
+		sourcePVC := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Writing data to the source volume")
+		writeTestFile(f, sourcePVC)
+
+		By("Taking a backup of the source volume and waiting for it to become available")
+		backupID := createBackup(f, sourcePVC)
+		Expect(f.WaitForBackupState(backupID, coreOCI.VolumeBackupLifecycleStateAvailable, backupTimeout)).NotTo(HaveOccurred())
+
+		By("Provisioning a new PVC from the snapshot")
+		restoredPVC := createAndAwaitPVCFromBackup(f, framework.ClassOCI, framework.MinVolumeBlock, backupID)
+
+		By("Verifying the restored volume contains the source data")
+		verifyTestFile(f, restoredPVC)
+	})
+
+	It("should restore a snapshot into a different availability domain [snapshot-cross-ad]", func() {
+		By("Creating a source PVC and waiting for it to be bound")
+		sourcePVC := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Writing data to the source volume")
+		writeTestFile(f, sourcePVC)
+
+		By("Taking a backup of the source volume")
+		backupID := createBackup(f, sourcePVC)
+
+		By("Provisioning a new PVC from the backup in a different AD")
+		restoredPVC := createAndAwaitPVCFromBackupInAD(f, framework.ClassOCI, framework.MinVolumeBlock, backupID, f.CheckEnvVar(framework.AD))
+
+		By("Verifying the restored volume contains the source data")
+		verifyTestFile(f, restoredPVC)
+	})
+
+	It("should keep the backup after its source PVC is deleted [snapshot-retain]", func() {
+		By("Creating a source PVC and waiting for it to be bound")
+		sourcePVC := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Taking a backup of the source volume")
+		backupID := createBackup(f, sourcePVC)
+		Expect(f.WaitForBackupState(backupID, coreOCI.VolumeBackupLifecycleStateAvailable, backupTimeout)).NotTo(HaveOccurred())
+
+		By("Deleting the source PVC")
+		Expect(f.ClientSet.CoreV1().PersistentVolumeClaims(f.Namespace.Name).Delete(sourcePVC.Name, nil)).NotTo(HaveOccurred())
+
+		By("Checking that the backup still exists")
+		Expect(f.WaitForBackupState(backupID, coreOCI.VolumeBackupLifecycleStateAvailable, backupTimeout)).NotTo(HaveOccurred())
+	})
+
+	It("should fail to take a backup when the source volume no longer exists [snapshot-source-missing]", func() {
+		By("Creating a source PVC and waiting for it to be bound")
+		sourcePVC := createAndAwaitPVC(f, framework.ClassOCI, framework.MinVolumeBlock)
+
+		By("Deleting the source PVC and its backing volume")
+		Expect(f.ClientSet.CoreV1().PersistentVolumeClaims(f.Namespace.Name).Delete(sourcePVC.Name, nil)).NotTo(HaveOccurred())
+
+		By("Attempting to take a backup of the deleted volume and expecting failure")
+		_, err := f.StorageBackend.CreateBackup(context.Background(), *volumeIDFromPVC(f, sourcePVC), framework.NodeTags())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// createAndAwaitPVC creates a PVC of the given storage class/size and waits for it to be bound.
+func createAndAwaitPVC(f *framework.Framework, class, size string) *v1.PersistentVolumeClaim {
+	return f.CreateAndAwaitPVC(f.NewPVC("snapshot-", class, size))
+}
+
+// createAndAwaitPVCFromBackup provisions a new PVC from the given backup id and waits for it to be bound.
+func createAndAwaitPVCFromBackup(f *framework.Framework, class, size, backupID string) *v1.PersistentVolumeClaim {
+	return createAndAwaitPVCFromBackupInAD(f, class, size, backupID, "")
+}
+
+// createAndAwaitPVCFromBackupInAD provisions a new PVC from the given backup id in the given
+// availability domain (or the default AD if ad is empty) and waits for it to be bound.
+func createAndAwaitPVCFromBackupInAD(f *framework.Framework, class, size, backupID, ad string) *v1.PersistentVolumeClaim {
+	pvc := f.NewPVC("snapshot-", class, size)
+	pvc.Annotations = map[string]string{
+		"volume.beta.kubernetes.io/oci-volume-source": backupID,
+	}
+	if ad != "" {
+		pvc.Annotations["volume.beta.kubernetes.io/oci-volume-ad"] = ad
+	}
+	return f.CreateAndAwaitPVC(pvc)
+}
+
+// createBackup takes a backup of the volume backing pvc and registers it for cleanup.
+func createBackup(f *framework.Framework, pvc *v1.PersistentVolumeClaim) string {
+	id, err := f.StorageBackend.CreateBackup(context.Background(), *volumeIDFromPVC(f, pvc), framework.NodeTags())
+	Expect(err).NotTo(HaveOccurred())
+	f.SnapshotIDs = append(f.SnapshotIDs, id)
+	return id
+}
+
+func writeTestFile(f *framework.Framework, pvc *v1.PersistentVolumeClaim) {
+	f.RunInPodWithPVC(pvc, "echo snapshot-e2e-data > /data/snapshot.txt")
+}
+
+func verifyTestFile(f *framework.Framework, pvc *v1.PersistentVolumeClaim) {
+	f.RunInPodWithPVC(pvc, "grep snapshot-e2e-data /data/snapshot.txt")
+}
+
+// volumeIDFromPVC returns the OCI volume OCID backing the given bound PVC,
+// as stamped onto the PV's FlexVolume options by the provisioner.
+func volumeIDFromPVC(f *framework.Framework, pvc *v1.PersistentVolumeClaim) *string {
+	pv, err := f.ClientSet.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(pv.Spec.FlexVolume).NotTo(BeNil(), fmt.Sprintf("pv %q for pvc %q is not FlexVolume-backed", pv.Name, pvc.Name))
+	volumeID, ok := pv.Spec.FlexVolume.Options["volumeID"]
+	Expect(ok).To(BeTrue(), fmt.Sprintf("pv %q is missing its volumeID FlexVolume option", pv.Name))
+	return &volumeID
+}